@@ -0,0 +1,70 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package common
+
+import (
+	"testing"
+
+	null "gopkg.in/guregu/null.v3"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleRateFor(t *testing.T) {
+	c := &Collector{
+		Config: Config{
+			SampleRate: null.FloatFrom(0.5),
+			SampleRates: map[string]float64{
+				"http_req_duration": 0.1,
+			},
+		},
+	}
+
+	assert.Equal(t, 0.1, c.sampleRateFor("http_req_duration"))
+	assert.Equal(t, 0.5, c.sampleRateFor("checks"))
+
+	c.Config.SampleRate = null.Float{}
+	assert.Equal(t, float64(1), c.sampleRateFor("checks"))
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := &Collector{}
+	c.droppedSamples = 3
+	c.flushFailures = 2
+
+	assert.Equal(t, "dropped_samples=3 flush_failures=2", c.Diagnostics())
+}
+
+func TestRunInfoString(t *testing.T) {
+	assert.Equal(t, "", RunInfo{}.String())
+
+	info := RunInfo{
+		ScriptName: "script.js",
+		VUs:        10,
+		Stages:     []string{"30s@10", "1m@50"},
+		Thresholds: []string{"http_req_duration"},
+	}
+	assert.Equal(
+		t,
+		"script=script.js vus=10 stages=30s@10,1m@50 thresholds=http_req_duration",
+		info.String(),
+	)
+}
@@ -0,0 +1,126 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package common
+
+import (
+	null "gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib/types"
+)
+
+// MetricTypeHistogram sends stats.Trend samples via the DogStatsD HISTOGRAM type, which is
+// aggregated per-agent into min/max/avg/median/percentiles/count
+const MetricTypeHistogram = "histogram"
+
+// MetricTypeDistribution sends stats.Trend samples as a DogStatsD DISTRIBUTION, which is
+// aggregated globally on the Datadog side instead of per-agent
+const MetricTypeDistribution = "distribution"
+
+// MetricTypeTiming sends stats.Trend samples via TimeInMilliseconds, the historical default
+const MetricTypeTiming = "timing"
+
+// Config is the common config for statsd-compatible collectors (statsd, datadog)
+type Config struct {
+	Addr         null.String        `json:"addr" envconfig:"ADDR"`
+	BufferSize   null.Int           `json:"bufferSize" envconfig:"BUFFER_SIZE"`
+	Namespace    null.String        `json:"namespace" envconfig:"NAMESPACE"`
+	PushInterval types.NullDuration `json:"pushInterval" envconfig:"PUSH_INTERVAL"`
+
+	// MetricType selects how stats.Trend samples are reported: "histogram" (default),
+	// "distribution" or "timing". See MetricTypeHistogram, MetricTypeDistribution and
+	// MetricTypeTiming.
+	MetricType null.String `json:"metricType" envconfig:"METRIC_TYPE"`
+	// DistributionMetrics overrides MetricType to "distribution" for the listed k6 metric
+	// names only, regardless of the global MetricType setting.
+	DistributionMetrics []string `json:"distributionMetrics" envconfig:"DISTRIBUTION_METRICS"`
+
+	// MaxMessagesPerPayload caps how many statsd messages are batched into a single payload.
+	// Applies to both transports; if unset, the client's own default is used (which is
+	// chosen based on the MTU for UDP).
+	MaxMessagesPerPayload null.Int `json:"maxMessagesPerPayload" envconfig:"MAX_MESSAGES_PER_PAYLOAD"`
+	// WriteTimeout bounds how long a write to the Unix Domain Socket may block before it's
+	// considered failed. Only applies when Addr uses the unix:// scheme.
+	WriteTimeout types.NullDuration `json:"writeTimeout" envconfig:"WRITE_TIMEOUT"`
+
+	// SampleRate is the default DogStatsD sample rate applied to every metric, in the range
+	// (0, 1]. It's forwarded as-is to the statsd client, which draws against it to decide
+	// whether to put a given sample on the wire and tags the payload with it so the Agent
+	// scales the aggregated value back up; values below 1 are not also filtered locally.
+	SampleRate null.Float `json:"sampleRate" envconfig:"SAMPLE_RATE"`
+	// SampleRates overrides SampleRate for specific k6 metric names, e.g. to sub-sample a
+	// high-cardinality metric like http_req_duration while keeping low-frequency metrics
+	// like checks at a rate of 1.
+	SampleRates map[string]float64 `json:"sampleRates" envconfig:"SAMPLE_RATES"`
+
+	// MaxRetries caps how many additional attempts are made to flush a batch after the first
+	// one fails, before the batch's flush error is reported as permanent.
+	MaxRetries null.Int `json:"maxRetries" envconfig:"MAX_RETRIES"`
+	// InitialBackoff is the base delay before the first flush retry; it doubles after each
+	// subsequent failed attempt, up to MaxBackoff. Each wait adds a random jitter of up to
+	// the current base delay, and the total wait is capped at MaxBackoff.
+	InitialBackoff types.NullDuration `json:"initialBackoff" envconfig:"INITIAL_BACKOFF"`
+	// MaxBackoff caps the exponential backoff delay between flush retries.
+	MaxBackoff types.NullDuration `json:"maxBackoff" envconfig:"MAX_BACKOFF"`
+}
+
+// Apply merges two configs by overwriting properties in the old config
+func (c Config) Apply(cfg Config) Config {
+	if cfg.Addr.Valid {
+		c.Addr = cfg.Addr
+	}
+	if cfg.BufferSize.Valid {
+		c.BufferSize = cfg.BufferSize
+	}
+	if cfg.Namespace.Valid {
+		c.Namespace = cfg.Namespace
+	}
+	if cfg.PushInterval.Valid {
+		c.PushInterval = cfg.PushInterval
+	}
+	if cfg.MetricType.Valid {
+		c.MetricType = cfg.MetricType
+	}
+	if len(cfg.DistributionMetrics) > 0 {
+		c.DistributionMetrics = cfg.DistributionMetrics
+	}
+	if cfg.MaxMessagesPerPayload.Valid {
+		c.MaxMessagesPerPayload = cfg.MaxMessagesPerPayload
+	}
+	if cfg.WriteTimeout.Valid {
+		c.WriteTimeout = cfg.WriteTimeout
+	}
+	if cfg.SampleRate.Valid {
+		c.SampleRate = cfg.SampleRate
+	}
+	if len(cfg.SampleRates) > 0 {
+		c.SampleRates = cfg.SampleRates
+	}
+	if cfg.MaxRetries.Valid {
+		c.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.InitialBackoff.Valid {
+		c.InitialBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxBackoff.Valid {
+		c.MaxBackoff = cfg.MaxBackoff
+	}
+	return c
+}
@@ -0,0 +1,172 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+	null "gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib/types"
+	"github.com/loadimpact/k6/stats"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStatsdClient is a statsdClient that never touches the network, so commit/flushWithRetry
+// can be exercised without a real DogStatsD agent.
+type fakeStatsdClient struct {
+	flushCalls int
+	failUntil  int // Flush fails on calls 1..failUntil, then succeeds
+
+	counts []fakeCount
+}
+
+type fakeCount struct {
+	name  string
+	value int64
+}
+
+func (f *fakeStatsdClient) Count(name string, value int64, _ []string, _ float64) error {
+	f.counts = append(f.counts, fakeCount{name: name, value: value})
+	return nil
+}
+
+func (f *fakeStatsdClient) Gauge(string, float64, []string, float64) error { return nil }
+
+func (f *fakeStatsdClient) TimeInMilliseconds(string, float64, []string, float64) error {
+	return nil
+}
+
+func (f *fakeStatsdClient) Histogram(string, float64, []string, float64) error { return nil }
+
+func (f *fakeStatsdClient) Distribution(string, float64, []string, float64) error { return nil }
+
+func (f *fakeStatsdClient) Event(*statsd.Event) error { return nil }
+
+func (f *fakeStatsdClient) ServiceCheck(*statsd.ServiceCheck) error { return nil }
+
+func (f *fakeStatsdClient) Close() error { return nil }
+
+func (f *fakeStatsdClient) Flush() error {
+	f.flushCalls++
+	if f.flushCalls <= f.failUntil {
+		return errors.New("flush failed")
+	}
+	return nil
+}
+
+func newTestCollector(client statsdClient) *Collector {
+	return &Collector{
+		Client: client,
+		Logger: log.NewEntry(log.New()),
+		rng:    rand.New(rand.NewSource(1)),
+		Config: Config{
+			MaxRetries:     null.IntFrom(5),
+			InitialBackoff: types.NullDuration{Duration: types.Duration(time.Millisecond), Valid: true},
+			MaxBackoff:     types.NullDuration{Duration: types.Duration(time.Millisecond), Valid: true},
+		},
+	}
+}
+
+func TestFlushWithRetryResendsSameDataOnSuccess(t *testing.T) {
+	fake := &fakeStatsdClient{failUntil: 2}
+	c := newTestCollector(fake)
+
+	samples := []*Sample{
+		{Type: stats.Counter, Metric: "my_counter", Value: 3},
+	}
+
+	err := c.flushWithRetry(context.Background(), samples)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fake.flushCalls) // 2 failures + 1 success
+
+	// One re-dispatch per failed attempt before the next Flush; each one must carry the same
+	// sample that failed to flush the first time, not an empty or different payload.
+	assert.Len(t, fake.counts, 2)
+	for _, call := range fake.counts {
+		assert.Equal(t, "my_counter", call.name)
+		assert.Equal(t, int64(3), call.value)
+	}
+}
+
+func TestFlushWithRetryGivesUpAfterMaxRetries(t *testing.T) {
+	fake := &fakeStatsdClient{failUntil: 100}
+	c := newTestCollector(fake)
+	c.Config.MaxRetries = null.IntFrom(2)
+
+	err := c.flushWithRetry(context.Background(), nil)
+	assert.Error(t, err)
+	assert.Equal(t, 3, fake.flushCalls) // initial attempt + 2 retries
+}
+
+func TestFlushWithRetryStopsOnContextCancel(t *testing.T) {
+	fake := &fakeStatsdClient{failUntil: 100}
+	c := newTestCollector(fake)
+	c.Config.MaxRetries = null.IntFrom(100)
+	c.Config.InitialBackoff = types.NullDuration{Duration: types.Duration(time.Hour), Valid: true}
+	c.Config.MaxBackoff = types.NullDuration{Duration: types.Duration(time.Hour), Valid: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := c.flushWithRetry(ctx, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, fake.flushCalls)
+}
+
+// TestCommitCollapsesErrorsAcrossMetrics verifies that the same underlying transport error,
+// hit while dispatching several different metric names, collapses into a single multiError
+// group instead of one per metric.
+func TestCommitCollapsesErrorsAcrossMetrics(t *testing.T) {
+	fake := &failingCountClient{}
+	c := newTestCollector(fake)
+	c.Config.MaxRetries = null.IntFrom(0)
+
+	data := []*Sample{
+		{Type: stats.Counter, Metric: "http_req_duration", Value: 1},
+		{Type: stats.Counter, Metric: "vus", Value: 1},
+		{Type: stats.Counter, Metric: "iterations", Value: 1},
+	}
+
+	err := c.commit(context.Background(), data)
+	assert.Error(t, err)
+
+	merr, ok := err.(*multiError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(merr.counts))
+	assert.Equal(t, 3, merr.counts["connection refused"])
+}
+
+// failingCountClient fails every Count call with the same error, regardless of metric name.
+type failingCountClient struct {
+	fakeStatsdClient
+}
+
+func (f *failingCountClient) Count(string, int64, []string, float64) error {
+	return errors.New("connection refused")
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// multiError aggregates the errors encountered while pushing a single batch of samples,
+// grouping them by their error string so that, e.g., one dropped connection that fails every
+// sample in a batch collapses into a single counted entry instead of one log line each.
+type multiError struct {
+	counts map[string]int
+}
+
+func newMultiError() *multiError {
+	return &multiError{counts: make(map[string]int)}
+}
+
+// Add records err, if it isn't nil, under its error-class (its Error() string).
+func (m *multiError) Add(err error) {
+	if err == nil {
+		return
+	}
+	m.counts[err.Error()]++
+}
+
+// Len returns the total number of errors recorded, across all classes.
+func (m *multiError) Len() int {
+	n := 0
+	for _, count := range m.counts {
+		n += count
+	}
+	return n
+}
+
+// ErrorOrNil returns m if it holds at least one error, nil otherwise.
+func (m *multiError) ErrorOrNil() error {
+	if len(m.counts) == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *multiError) Error() string {
+	classes := make([]string, 0, len(m.counts))
+	for errStr := range m.counts {
+		classes = append(classes, errStr)
+	}
+	sort.Strings(classes)
+
+	parts := make([]string, 0, len(classes))
+	for _, errStr := range classes {
+		parts = append(parts, fmt.Sprintf("%s (x%d)", errStr, m.counts[errStr]))
+	}
+	return strings.Join(parts, "; ")
+}
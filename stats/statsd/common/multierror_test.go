@@ -0,0 +1,50 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiErrorEmpty(t *testing.T) {
+	errs := newMultiError()
+	assert.Nil(t, errs.ErrorOrNil())
+	assert.Equal(t, 0, errs.Len())
+}
+
+func TestMultiErrorGroupsByClass(t *testing.T) {
+	errs := newMultiError()
+	errs.Add(errors.New("connection refused"))
+	errs.Add(errors.New("connection refused"))
+	errs.Add(errors.New("connection refused"))
+	errs.Add(errors.New("timeout"))
+	errs.Add(nil)
+
+	assert.Equal(t, 4, errs.Len())
+
+	err := errs.ErrorOrNil()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused (x3)")
+	assert.Contains(t, err.Error(), "timeout (x1)")
+}
@@ -0,0 +1,41 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package common
+
+import "github.com/loadimpact/k6/stats"
+
+// Sample is the internal, flattened representation of a stats.Sample that's ready to be
+// pushed to a statsd-compatible collector
+type Sample struct {
+	Type   stats.MetricType
+	Metric string
+	Value  float64
+	Tags   map[string]string
+}
+
+func generateDataPoint(sample stats.Sample) *Sample {
+	return &Sample{
+		Type:   sample.Metric.Type,
+		Metric: sample.Metric.Name,
+		Value:  sample.Value,
+		Tags:   sample.Tags.CloneTags(),
+	}
+}